@@ -0,0 +1,317 @@
+package buckets
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// A DB is a bolt database with convenience methods for working with buckets.
+//
+// A DB embeds the exposed bolt.DB methods.
+type DB struct {
+	*bolt.DB
+}
+
+// Open creates/opens a buckets database at the specified path.
+func Open(path string) (*DB, error) {
+	config := &bolt.Options{Timeout: 1 * time.Second}
+	db, err := bolt.Open(path, 0600, config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open %s: %s", path, err)
+	}
+	return &DB{db}, nil
+}
+
+// New creates/opens a named bucket.
+func (db *DB) New(name []byte) (*Bucket, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(name)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Bucket{db, name}, nil
+}
+
+// Delete removes the named bucket.
+func (db *DB) Delete(name []byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(name)
+	})
+}
+
+// Close releases the per-bucket watch/index state and the Stats counters
+// tracked for db (see watch.go, index.go, stats.go) before closing the
+// underlying bolt database, so a long-running process doesn't leak that
+// state across repeated Open/Close cycles.
+func (db *DB) Close() error {
+	evictWatchState(db)
+	evictIndexState(db)
+	evictStats(db)
+	return db.DB.Close()
+}
+
+/* -- ITEM -- */
+
+// An Item holds a key/value pair.
+type Item struct {
+	Key   []byte
+	Value []byte
+}
+
+/* -- BUCKET-- */
+
+// Bucket represents a collection of key/value pairs inside the database.
+type Bucket struct {
+	db   *DB
+	Name []byte
+}
+
+// bucketKey identifies a named bucket within a DB. DB.New allocates a
+// fresh *Bucket on every call, so two handles obtained for the same name
+// are different pointers; the watch/index side-tables (see watch.go,
+// index.go) key off bucketKey instead of *Bucket so independently
+// obtained handles for the same bucket share the same state.
+type bucketKey struct {
+	db   *DB
+	name string
+}
+
+func (bk *Bucket) key() bucketKey {
+	return bucketKey{bk.db, string(bk.Name)}
+}
+
+// Put inserts value `v` with key `k`, then reports the mutation to any
+// registered indices, watchers, and the Stats counters (see watch.go,
+// index.go, stats.go).
+func (bk *Bucket) Put(k, v []byte) error {
+	start := time.Now()
+	var old []byte
+	err := bk.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bk.Name)
+		if existing := b.Get(k); existing != nil {
+			old = append([]byte{}, existing...)
+		}
+		return b.Put(k, v)
+	})
+	recordTxDuration(bk.db, time.Since(start))
+	if err != nil {
+		return err
+	}
+	bk.notify(Event{Op: Put, Key: k, Value: v, OldValue: old})
+	return nil
+}
+
+// PutNX (put-if-not-exists) inserts value `v` with key `k`
+// if key doesn't exist.
+func (bk *Bucket) PutNX(k, v []byte) error {
+	existing, err := bk.Get(k)
+	if existing != nil || err != nil {
+		return err
+	}
+	return bk.Put(k, v)
+}
+
+// Insert iterates over a slice of k/v pairs, putting each item in
+// the bucket as part of a single transaction, then reports each one to
+// any registered indices, watchers, and the Stats counters, same as Put.
+// For large insertions, be sure to pre-sort your items (by Key in
+// byte-sorted order), which will result in much more efficient insertion
+// times and storage costs.
+func (bk *Bucket) Insert(items []struct{ Key, Value []byte }) error {
+	start := time.Now()
+	olds := make([][]byte, len(items))
+	err := bk.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bk.Name)
+		for i, item := range items {
+			if existing := b.Get(item.Key); existing != nil {
+				olds[i] = append([]byte{}, existing...)
+			}
+			if err := b.Put(item.Key, item.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	recordTxDuration(bk.db, time.Since(start))
+	if err != nil {
+		return err
+	}
+	for i, item := range items {
+		bk.notify(Event{Op: Put, Key: item.Key, Value: item.Value, OldValue: olds[i]})
+	}
+	return nil
+}
+
+// InsertNX (insert-if-not-exists) iterates over a slice of k/v pairs,
+// putting each item in the bucket as part of a single transaction.
+// Unlike Insert, however, InsertNX will not update the value for an
+// existing key. Only the items actually written are reported to any
+// registered indices, watchers, and the Stats counters.
+func (bk *Bucket) InsertNX(items []struct{ Key, Value []byte }) error {
+	start := time.Now()
+	var written []struct{ Key, Value []byte }
+	err := bk.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bk.Name)
+		for _, item := range items {
+			if b.Get(item.Key) != nil {
+				continue
+			}
+			if err := b.Put(item.Key, item.Value); err != nil {
+				return err
+			}
+			written = append(written, item)
+		}
+		return nil
+	})
+	recordTxDuration(bk.db, time.Since(start))
+	if err != nil {
+		return err
+	}
+	for _, item := range written {
+		bk.notify(Event{Op: Put, Key: item.Key, Value: item.Value})
+	}
+	return nil
+}
+
+// Delete removes key `k`, then reports the mutation to any registered
+// indices, watchers, and the Stats counters.
+func (bk *Bucket) Delete(k []byte) error {
+	start := time.Now()
+	var old []byte
+	err := bk.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bk.Name)
+		if existing := b.Get(k); existing != nil {
+			old = append([]byte{}, existing...)
+		}
+		return b.Delete(k)
+	})
+	recordTxDuration(bk.db, time.Since(start))
+	if err != nil {
+		return err
+	}
+	bk.notify(Event{Op: Delete, Key: k, OldValue: old})
+	return nil
+}
+
+// Get retrieves the value for key `k`.
+func (bk *Bucket) Get(k []byte) (value []byte, err error) {
+	err = bk.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bk.Name).Get(k)
+		if v != nil {
+			value = make([]byte, len(v))
+			copy(value, v)
+		}
+		return nil
+	})
+	recordGet(bk.db, len(value))
+	return value, err
+}
+
+// Items returns a slice of key/value pairs.  Each k/v pair in the slice
+// is of type Item (`struct{ Key, Value []byte }`).
+func (bk *Bucket) Items() (items []Item, err error) {
+	return items, bk.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bk.Name).Cursor()
+		var key, value []byte
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v != nil {
+				key = make([]byte, len(k))
+				copy(key, k)
+				value = make([]byte, len(v))
+				copy(value, v)
+				items = append(items, Item{key, value})
+			}
+		}
+		return nil
+	})
+}
+
+// PrefixItems returns a slice of key/value pairs for all keys with
+// a given prefix.  Each k/v pair in the slice is of type Item
+// (`struct{ Key, Value []byte }`).
+func (bk *Bucket) PrefixItems(pre []byte) (items []Item, err error) {
+	err = bk.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bk.Name).Cursor()
+		var key, value []byte
+		for k, v := c.Seek(pre); bytes.HasPrefix(k, pre); k, v = c.Next() {
+			if v != nil {
+				key = make([]byte, len(k))
+				copy(key, k)
+				value = make([]byte, len(v))
+				copy(value, v)
+				items = append(items, Item{key, value})
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// RangeItems returns a slice of key/value pairs for all keys within
+// a given range.  Each k/v pair in the slice is of type Item
+// (`struct{ Key, Value []byte }`).
+func (bk *Bucket) RangeItems(min []byte, max []byte) (items []Item, err error) {
+	err = bk.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bk.Name).Cursor()
+		var key, value []byte
+		for k, v := c.Seek(min); isBefore(k, max); k, v = c.Next() {
+			if v != nil {
+				key = make([]byte, len(k))
+				copy(key, k)
+				value = make([]byte, len(v))
+				copy(value, v)
+				items = append(items, Item{key, value})
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// Map applies `do` on each key/value pair.
+func (bk *Bucket) Map(do func(k, v []byte) error) error {
+	return bk.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bk.Name).ForEach(do)
+	})
+}
+
+// MapPrefix applies `do` on each k/v pair of keys with prefix.
+func (bk *Bucket) MapPrefix(do func(k, v []byte) error, pre []byte) error {
+	return bk.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bk.Name).Cursor()
+		for k, v := c.Seek(pre); bytes.HasPrefix(k, pre); k, v = c.Next() {
+			do(k, v)
+		}
+		return nil
+	})
+}
+
+// MapRange applies `do` on each k/v pair of keys within range.
+func (bk *Bucket) MapRange(do func(k, v []byte) error, min, max []byte) error {
+	return bk.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bk.Name).Cursor()
+		for k, v := c.Seek(min); isBefore(k, max); k, v = c.Next() {
+			do(k, v)
+		}
+		return nil
+	})
+}
+
+// NewPrefixScanner initializes a new prefix scanner.
+func (bk *Bucket) NewPrefixScanner(pre []byte) *PrefixScanner {
+	return &PrefixScanner{bk.db, bk.Name, pre}
+}
+
+// NewRangeScanner initializes a new range scanner.  It takes a `min` and a
+// `max` key for specifying the range paramaters.
+func (bk *Bucket) NewRangeScanner(min, max []byte) *RangeScanner {
+	return &RangeScanner{bk.db, bk.Name, min, max}
+}
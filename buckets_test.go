@@ -0,0 +1,95 @@
+package buckets
+
+import "testing"
+
+// TestInsertDrivesIndexAndWatch guards against Insert/InsertNX writing
+// directly against the bolt transaction and bypassing notify, which
+// would leave a registered index empty and deliver nothing to watchers
+// for data loaded via Insert (e.g. a streaming import).
+func TestInsertDrivesIndexAndWatch(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := todos.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+	ch := todos.Watch()
+
+	items := []struct{ Key, Value []byte }{
+		{[]byte("1"), []byte("alice:buy milk")},
+		{[]byte("2"), []byte("bob:walk dog")},
+	}
+	if err := todos.Insert(items); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	values, err := todos.QueryIndex("owner", []byte("alice"))
+	if err != nil {
+		t.Fatalf("QueryIndex: %v", err)
+	}
+	if len(values) != 1 {
+		t.Fatalf("QueryIndex(alice) after Insert = %v, want 1 value", values)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < len(items); i++ {
+		select {
+		case ev := <-ch:
+			seen[string(ev.Key)] = true
+		default:
+			t.Fatalf("Insert delivered only %d of %d events to the watcher", i, len(items))
+		}
+	}
+	for _, item := range items {
+		if !seen[string(item.Key)] {
+			t.Fatalf("watcher never saw an event for key %q", item.Key)
+		}
+	}
+}
+
+// TestInsertNXOnlyNotifiesWrittenItems checks that InsertNX reports only
+// the items it actually wrote, not ones it skipped because the key
+// already existed.
+func TestInsertNXOnlyNotifiesWrittenItems(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := todos.Put([]byte("1"), []byte("alice:buy milk")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	ch := todos.Watch()
+
+	items := []struct{ Key, Value []byte }{
+		{[]byte("1"), []byte("alice:buy oat milk")}, // already exists, should be skipped
+		{[]byte("2"), []byte("bob:walk dog")},
+	}
+	if err := todos.InsertNX(items); err != nil {
+		t.Fatalf("InsertNX: %v", err)
+	}
+
+	v, err := todos.Get([]byte("1"))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(v) != "alice:buy milk" {
+		t.Fatalf("Get(1) = %q, want unchanged %q", v, "alice:buy milk")
+	}
+
+	select {
+	case ev := <-ch:
+		if string(ev.Key) != "2" {
+			t.Fatalf("watcher event key = %q, want \"2\"", ev.Key)
+		}
+	default:
+		t.Fatal("expected one event for the item InsertNX actually wrote")
+	}
+	select {
+	case ev := <-ch:
+		t.Fatalf("unexpected extra event for key %q; InsertNX shouldn't notify skipped items", ev.Key)
+	default:
+	}
+}
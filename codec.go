@@ -0,0 +1,166 @@
+package buckets
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+// Codec marshals and unmarshals the Go values stored in a TypedBucket.
+// Implement it to plug in a format other than the built-in JSON, gob, and
+// msgpack codecs.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(b []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)   { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(b []byte, v interface{}) error { return json.Unmarshal(b, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(b []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)   { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(b []byte, v interface{}) error { return msgpack.Unmarshal(b, v) }
+
+// Built-in codecs, also registered under their name ("json", "gob",
+// "msgpack") for lookup via CodecByName.
+var (
+	JSON    Codec = jsonCodec{}
+	Gob     Codec = gobCodec{}
+	Msgpack Codec = msgpackCodec{}
+)
+
+var (
+	codecMu sync.Mutex
+	codecs  = map[string]Codec{
+		"json":    JSON,
+		"gob":     Gob,
+		"msgpack": Msgpack,
+	}
+)
+
+// RegisterCodec makes a user-supplied codec available via CodecByName
+// under name, alongside the built-in "json", "gob", and "msgpack" codecs.
+func RegisterCodec(name string, codec Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[name] = codec
+}
+
+// CodecByName looks up a codec previously registered with RegisterCodec,
+// or one of the built-ins.
+func CodecByName(name string) (Codec, bool) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	c, ok := codecs[name]
+	return c, ok
+}
+
+// ErrKeyNotFound is returned by TypedBucket.GetV when the key has no
+// stored value.
+var ErrKeyNotFound = errors.New("buckets: key not found")
+
+// TypedBucket wraps a *Bucket so callers can store and retrieve Go values
+// directly instead of hand-writing Marshal/Unmarshal helpers like the
+// Todo.Encode/decode pair in the examples package.
+type TypedBucket struct {
+	*Bucket
+	codec     Codec
+	prototype reflect.Type
+}
+
+// NewTyped opens (creating if necessary) the named bucket and wraps it as
+// a TypedBucket that encodes values with codec. prototype is a value of
+// the type stored in the bucket (e.g. Todo{} or &Todo{}); MapV uses it to
+// allocate a fresh value for each decoded entry.
+func (bx *DB) NewTyped(name []byte, codec Codec, prototype interface{}) (*TypedBucket, error) {
+	b, err := bx.New(name)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(prototype)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return &TypedBucket{Bucket: b, codec: codec, prototype: t}, nil
+}
+
+// PutV marshals value with the bucket's codec and stores it under key.
+func (tb *TypedBucket) PutV(key, value interface{}) error {
+	k, err := typedKey(key)
+	if err != nil {
+		return err
+	}
+	encoded, err := tb.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return tb.Put(k, encoded)
+}
+
+// GetV fetches the value stored under key and unmarshals it into dst,
+// which must be a pointer. It returns ErrKeyNotFound if key isn't set.
+func (tb *TypedBucket) GetV(key interface{}, dst interface{}) error {
+	k, err := typedKey(key)
+	if err != nil {
+		return err
+	}
+	v, err := tb.Get(k)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return ErrKeyNotFound
+	}
+	return tb.codec.Unmarshal(v, dst)
+}
+
+// MapV calls fn once per entry in the bucket, decoding each value with
+// the bucket's codec into a fresh instance of its prototype type before
+// passing it to fn.
+func (tb *TypedBucket) MapV(fn func(k, v interface{}) error) error {
+	return tb.Map(func(k, v []byte) error {
+		dst := reflect.New(tb.prototype).Interface()
+		if err := tb.codec.Unmarshal(v, dst); err != nil {
+			return err
+		}
+		return fn(k, dst)
+	})
+}
+
+// typedKey converts the key types PutV/GetV accept into the []byte keys
+// the underlying bucket stores.
+func typedKey(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return k, nil
+	case string:
+		return []byte(k), nil
+	case fmt.Stringer:
+		return []byte(k.String()), nil
+	default:
+		return nil, fmt.Errorf("buckets: unsupported key type %T", key)
+	}
+}
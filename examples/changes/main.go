@@ -0,0 +1,179 @@
+// This example exposes a bucket's change feed over HTTP at
+// GET /todos/_changes?since=<txid>, supporting both a long-poll mode (the
+// default) and Server-Sent Events (when the client sends
+// "Accept: text/event-stream"), so browser clients can react to writes
+// made by other clients.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joyrexus/buckets"
+)
+
+// longPollTimeout bounds how long a long-poll request waits for a new
+// event before returning an empty result.
+const longPollTimeout = 30 * time.Second
+
+func main() {
+	bx, err := buckets.Open(tempFilePath())
+	if err != nil {
+		log.Fatalf("couldn't open db: %v", err)
+	}
+	defer os.Remove(bx.Path())
+	defer bx.Close()
+
+	todos, err := bx.New([]byte("todos"))
+	if err != nil {
+		log.Fatalf("couldn't create todos bucket: %v", err)
+	}
+
+	http.HandleFunc("/todos/_changes", changesHandler(todos))
+	srv := httptest.NewServer(http.DefaultServeMux)
+	defer srv.Close()
+
+	log.Printf("serving change feed at %s/todos/_changes", srv.URL)
+}
+
+func changesHandler(b *buckets.Bucket) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+		if err != nil && r.URL.Query().Get("since") != "" {
+			http.Error(w, "invalid since cursor", http.StatusBadRequest)
+			return
+		}
+
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			serveSSE(w, r, b, since)
+			return
+		}
+		serveLongPoll(w, r, b, since)
+	}
+}
+
+// serveLongPoll responds with every event since the cursor, or - if there
+// are none yet - waits up to longPollTimeout for the next one before
+// responding with an empty array.
+func serveLongPoll(w http.ResponseWriter, r *http.Request, b *buckets.Bucket, since uint64) {
+	// Register the watcher before reading the backfill, not after, so an
+	// event landing in the gap between the two can't be missed entirely:
+	// it'll show up in events, on ch, or both (deduped below by TxID).
+	ch := b.Watch()
+
+	events, ok := b.Changes(since)
+	if !ok {
+		http.Error(w, "since cursor is older than the retained event history", http.StatusGone)
+		return
+	}
+
+	maxSeen := since
+	for _, ev := range events {
+		if ev.TxID > maxSeen {
+			maxSeen = ev.TxID
+		}
+	}
+
+	if len(events) == 0 {
+		timeout := time.After(longPollTimeout)
+	wait:
+		for {
+			select {
+			case ev := <-ch:
+				if ev.TxID <= maxSeen {
+					continue wait
+				}
+				events = []buckets.Event{ev}
+			case <-timeout:
+			case <-r.Context().Done():
+				return
+			}
+			break
+		}
+	}
+
+	if events == nil {
+		events = []buckets.Event{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// serveSSE first replays any buffered events since the cursor, then
+// streams new ones as they happen until the client disconnects.
+func serveSSE(w http.ResponseWriter, r *http.Request, b *buckets.Bucket, since uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Register the watcher before reading the backfill, not after, so an
+	// event landing in the gap between the two can't be missed entirely:
+	// it'll show up in events, on ch, or both (deduped below by TxID).
+	ch := b.Watch()
+
+	events, ok := b.Changes(since)
+	if !ok {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: error\ndata: since cursor is older than the retained event history\n\n")
+		flusher.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	maxSeen := since
+	for _, ev := range events {
+		writeSSE(w, ev)
+		if ev.TxID > maxSeen {
+			maxSeen = ev.TxID
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case ev := <-ch:
+			if ev.TxID <= maxSeen {
+				continue
+			}
+			maxSeen = ev.TxID
+			writeSSE(w, ev)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSE(w io.Writer, ev buckets.Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", ev.TxID, data)
+}
+
+// tempFilePath returns a temporary file path.
+func tempFilePath() string {
+	f, _ := ioutil.TempFile("", "bolt-")
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,95 @@
+// This example registers a secondary index on Todo.Day and serves
+// GET /todos?day=mon by querying it instead of scanning the bucket.
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/joyrexus/buckets"
+)
+
+// Todo holds a task description and the day of week in which to do it.
+type Todo struct {
+	Task string
+	Day  string
+}
+
+func main() {
+	bx, err := buckets.Open(tempFilePath())
+	if err != nil {
+		log.Fatalf("couldn't open db: %v", err)
+	}
+	defer os.Remove(bx.Path())
+	defer bx.Close()
+
+	todos, err := bx.New([]byte("todos"))
+	if err != nil {
+		log.Fatalf("couldn't create todos bucket: %v", err)
+	}
+
+	if err := todos.AddIndex("day", byDay); err != nil {
+		log.Fatalf("couldn't add day index: %v", err)
+	}
+
+	http.Handle("/todos", todosHandler{todos})
+	srv := httptest.NewServer(http.DefaultServeMux)
+	defer srv.Close()
+
+	log.Printf("serving %s/todos?day=mon", srv.URL)
+}
+
+// byDay is the IndexExtractor registered as the "day" index: it maps
+// each todo to its Day field.
+func byDay(k, v []byte) ([][]byte, error) {
+	var todo Todo
+	if err := json.Unmarshal(v, &todo); err != nil {
+		return nil, err
+	}
+	if todo.Day == "" {
+		return nil, nil
+	}
+	return [][]byte{[]byte(todo.Day)}, nil
+}
+
+type todosHandler struct {
+	todos *buckets.Bucket
+}
+
+func (h todosHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	day := r.URL.Query().Get("day")
+	if day == "" {
+		http.Error(w, "missing ?day= query parameter", http.StatusBadRequest)
+		return
+	}
+
+	values, err := h.todos.QueryIndex("day", []byte(day))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	todos := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		todos[i] = v
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todos)
+}
+
+// tempFilePath returns a temporary file path.
+func tempFilePath() string {
+	f, _ := ioutil.TempFile("", "bolt-")
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,83 @@
+// This example builds a production-shaped version of the post-and-store
+// service: it exposes /metrics and /healthz alongside the todos route,
+// and uses serverutil.RunHTTP for graceful shutdown instead of a bare
+// `defer db.Close()`.
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joyrexus/buckets"
+	"github.com/joyrexus/buckets/serverutil"
+)
+
+func main() {
+	bx, err := buckets.Open(tempFilePath())
+	if err != nil {
+		log.Fatalf("couldn't open db: %v", err)
+	}
+	defer os.Remove(bx.Path())
+
+	todos, err := bx.New([]byte("todos"))
+	if err != nil {
+		log.Fatalf("couldn't create todos bucket: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/todos/", todosHandler{todos})
+	mux.Handle("/metrics", bx.MetricsHandler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := bx.HealthCheck(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	srv := &http.Server{Addr: ":8080", Handler: mux}
+	if err := serverutil.RunHTTP(context.Background(), srv, bx); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+type todosHandler struct {
+	todos *buckets.Bucket
+}
+
+func (h todosHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := []byte(r.URL.Path)
+	switch r.Method {
+	case http.MethodGet:
+		v, err := h.todos.Get(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if v == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(v)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// tempFilePath returns a temporary file path.
+func tempFilePath() string {
+	f, _ := ioutil.TempFile("", "bolt-")
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	return f.Name()
+}
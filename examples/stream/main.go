@@ -0,0 +1,72 @@
+// This example shows how to back up and restore an entire bucket over
+// HTTP using the streaming ndjson format from Bucket.EncodeJSONStream and
+// Bucket.DecodeJSONStream, without ever loading the whole bucket into
+// memory.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/joyrexus/buckets"
+)
+
+func main() {
+	bx, err := buckets.Open(tempFilePath())
+	if err != nil {
+		log.Fatalf("couldn't open db: %v", err)
+	}
+	defer os.Remove(bx.Path())
+	defer bx.Close()
+
+	todos, err := bx.New([]byte("todos"))
+	if err != nil {
+		log.Fatalf("couldn't create todos bucket: %v", err)
+	}
+
+	http.Handle("/todos.ndjson", &ndjsonHandler{todos})
+	srv := httptest.NewServer(http.DefaultServeMux)
+	defer srv.Close()
+
+	log.Printf("serving bucket snapshots at %s/todos.ndjson", srv.URL)
+}
+
+// ndjsonHandler exposes a bucket's full contents as a streamed ndjson
+// backup (GET) and accepts the same format to restore one (POST).
+type ndjsonHandler struct {
+	bucket *buckets.Bucket
+}
+
+func (h *ndjsonHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if err := h.bucket.EncodeJSONStream(w); err != nil {
+			log.Printf("export failed: %v", err)
+		}
+	case http.MethodPost:
+		opts := buckets.ImportOptions{BatchSize: 100}
+		if err := h.bucket.DecodeJSONStream(r.Body, opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// tempFilePath returns a temporary file path.
+func tempFilePath() string {
+	f, _ := ioutil.TempFile("", "bolt-")
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	return f.Name()
+}
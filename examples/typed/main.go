@@ -0,0 +1,60 @@
+// This example mirrors the post-and-store example in the examples
+// package, but stores Todos through a *buckets.TypedBucket instead of
+// hand-writing Encode/decode helpers.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/joyrexus/buckets"
+)
+
+// Todo holds a task description and the day of week in which to do it.
+type Todo struct {
+	Task string
+	Day  string
+}
+
+func main() {
+	bx, err := buckets.Open(tempFilePath())
+	if err != nil {
+		log.Fatalf("couldn't open db: %v", err)
+	}
+	defer os.Remove(bx.Path())
+	defer bx.Close()
+
+	todos, err := bx.NewTyped([]byte("todos"), buckets.JSON, Todo{})
+	if err != nil {
+		log.Fatalf("couldn't create todos bucket: %v", err)
+	}
+
+	if err := todos.PutV("/mon", &Todo{Day: "mon", Task: "milk cows"}); err != nil {
+		log.Fatal(err)
+	}
+
+	var got Todo
+	if err := todos.GetV("/mon", &got); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("/mon: %+v", got)
+
+	todos.MapV(func(k, v interface{}) error {
+		todo := v.(*Todo)
+		log.Printf("%s: %s", k, todo.Task)
+		return nil
+	})
+}
+
+// tempFilePath returns a temporary file path.
+func tempFilePath() string {
+	f, _ := ioutil.TempFile("", "bolt-")
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,192 @@
+package buckets
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// IndexExtractor computes the set of index values a key/value pair maps
+// to, e.g. extracting a Todo's Day field. Returning no values excludes
+// the pair from the index.
+type IndexExtractor func(k, v []byte) ([][]byte, error)
+
+// index holds one secondary index's entries: index value -> primary key
+// -> primary value.
+type index struct {
+	extract IndexExtractor
+	mu      sync.RWMutex
+	entries map[string]map[string][]byte
+}
+
+// indexState holds the registered indices for one bucket. It's keyed by
+// bucketKey rather than by the bucket's own identity so that two
+// *Bucket handles obtained for the same name (DB.New allocates a fresh
+// *Bucket on every call) share the same registered indices.
+type indexState struct {
+	mu     sync.Mutex
+	byName map[string]*index
+}
+
+var (
+	indexMu     sync.Mutex
+	indexStates = map[bucketKey]*indexState{}
+)
+
+func indicesFor(b *Bucket) *indexState {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	k := b.key()
+	s, ok := indexStates[k]
+	if !ok {
+		s = &indexState{byName: map[string]*index{}}
+		indexStates[k] = s
+	}
+	return s
+}
+
+// evictIndexState drops the index state tracked for every bucket opened
+// from db. It's called from DB.Close so a long-running process that
+// repeatedly opens and closes databases doesn't leak an indexState per
+// bucket forever.
+func evictIndexState(db *DB) {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	for k := range indexStates {
+		if k.db == db {
+			delete(indexStates, k)
+		}
+	}
+}
+
+// AddIndex registers a secondary index called name, deriving its values
+// from each key/value pair via extract. The index is built from the
+// bucket's current contents immediately (so registering it on open
+// rebuilds it from whatever was already stored), then kept up to date
+// as Put and Delete run.
+func (b *Bucket) AddIndex(name string, extract IndexExtractor) error {
+	idx := &index{extract: extract, entries: map[string]map[string][]byte{}}
+
+	if err := b.Map(func(k, v []byte) error {
+		return idx.add(k, v)
+	}); err != nil {
+		return err
+	}
+
+	s := indicesFor(b)
+	s.mu.Lock()
+	s.byName[name] = idx
+	s.mu.Unlock()
+	return nil
+}
+
+// QueryIndex returns the primary values whose extracted index values
+// include value, for the named index.
+func (b *Bucket) QueryIndex(name string, value []byte) ([][]byte, error) {
+	idx, err := namedIndex(b, name)
+	if err != nil {
+		return nil, err
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	by, ok := idx.entries[string(value)]
+	if !ok {
+		return nil, nil
+	}
+	out := make([][]byte, 0, len(by))
+	for _, v := range by {
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// RangeIndex returns the primary values for every index value in
+// [start, end), for the named index.
+func (b *Bucket) RangeIndex(name string, start, end []byte) ([][]byte, error) {
+	idx, err := namedIndex(b, name)
+	if err != nil {
+		return nil, err
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var out [][]byte
+	lo, hi := string(start), string(end)
+	for val, by := range idx.entries {
+		if val >= lo && val < hi {
+			for _, v := range by {
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+func namedIndex(b *Bucket, name string) (*index, error) {
+	s := indicesFor(b)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, ok := s.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("buckets: no such index %q", name)
+	}
+	return idx, nil
+}
+
+func (idx *index) add(k, v []byte) error {
+	values, err := idx.extract(k, v)
+	if err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, val := range values {
+		by, ok := idx.entries[string(val)]
+		if !ok {
+			by = map[string][]byte{}
+			idx.entries[string(val)] = by
+		}
+		by[string(k)] = append([]byte{}, v...)
+	}
+	return nil
+}
+
+func (idx *index) remove(k []byte) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for val, by := range idx.entries {
+		if _, ok := by[string(k)]; ok {
+			delete(by, string(k))
+			if len(by) == 0 {
+				delete(idx.entries, val)
+			}
+		}
+	}
+}
+
+// notifyIndices keeps every index registered on b up to date after a
+// successful Put or Delete. The mutation has already committed by the
+// time this runs, so a failing IndexExtractor can't reject it; instead
+// its error is logged so the desync is at least visible. The key's old
+// entries are removed before the extractor runs, so on such an error the
+// key is left out of that index altogether.
+func notifyIndices(b *Bucket, ev Event) {
+	s := indicesFor(b)
+	s.mu.Lock()
+	names := make([]string, 0, len(s.byName))
+	indices := make([]*index, 0, len(s.byName))
+	for name, idx := range s.byName {
+		names = append(names, name)
+		indices = append(indices, idx)
+	}
+	s.mu.Unlock()
+
+	for i, idx := range indices {
+		idx.remove(ev.Key)
+		if ev.Op == Put {
+			if err := idx.add(ev.Key, ev.Value); err != nil {
+				log.Printf("buckets: index %q: failed to index key %q: %v", names[i], ev.Key, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package buckets
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "bolt.db"))
+	if err != nil {
+		t.Fatalf("couldn't open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// byOwner is an IndexExtractor keyed on the bytes before the first colon
+// in the value, e.g. "alice:buy milk" indexes under "alice".
+func byOwner(k, v []byte) ([][]byte, error) {
+	i := -1
+	for j, b := range v {
+		if b == ':' {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("byOwner: no owner prefix in %q", v)
+	}
+	return [][]byte{v[:i]}, nil
+}
+
+func TestAddIndexAndQuery(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+
+	if err := todos.Put([]byte("1"), []byte("alice:buy milk")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := todos.Put([]byte("2"), []byte("bob:walk dog")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := todos.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+
+	values, err := todos.QueryIndex("owner", []byte("alice"))
+	if err != nil {
+		t.Fatalf("QueryIndex: %v", err)
+	}
+	if len(values) != 1 || string(values[0]) != "alice:buy milk" {
+		t.Fatalf("QueryIndex(alice) = %v, want [alice:buy milk]", values)
+	}
+
+	if _, err := todos.QueryIndex("nope", []byte("alice")); err == nil {
+		t.Fatal("QueryIndex on an unregistered index should error")
+	}
+}
+
+func TestIndexTracksPutAndDelete(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := todos.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+
+	if err := todos.Put([]byte("1"), []byte("alice:buy milk")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if values, _ := todos.QueryIndex("owner", []byte("alice")); len(values) != 1 {
+		t.Fatalf("QueryIndex(alice) after Put = %v, want 1 value", values)
+	}
+
+	// Re-putting under a new owner should move the entry, not duplicate it.
+	if err := todos.Put([]byte("1"), []byte("bob:buy milk")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if values, _ := todos.QueryIndex("owner", []byte("alice")); len(values) != 0 {
+		t.Fatalf("QueryIndex(alice) after reassignment = %v, want none", values)
+	}
+	if values, _ := todos.QueryIndex("owner", []byte("bob")); len(values) != 1 {
+		t.Fatalf("QueryIndex(bob) after reassignment = %v, want 1 value", values)
+	}
+
+	if err := todos.Delete([]byte("1")); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if values, _ := todos.QueryIndex("owner", []byte("bob")); len(values) != 0 {
+		t.Fatalf("QueryIndex(bob) after Delete = %v, want none", values)
+	}
+}
+
+func TestRangeIndex(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := todos.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+
+	for i, owner := range []string{"alice", "bob", "carl"} {
+		k := []byte{byte('1' + i)}
+		if err := todos.Put(k, []byte(owner+":task")); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	values, err := todos.RangeIndex("owner", []byte("alice"), []byte("carl"))
+	if err != nil {
+		t.Fatalf("RangeIndex: %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("RangeIndex(alice, carl) = %d values, want 2", len(values))
+	}
+}
+
+func TestCloseEvictsIndexState(t *testing.T) {
+	db := openTestDB(t)
+	todos, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := todos.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+
+	indexMu.Lock()
+	_, tracked := indexStates[todos.key()]
+	indexMu.Unlock()
+	if !tracked {
+		t.Fatal("expected indexStates to track the bucket before Close")
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	indexMu.Lock()
+	_, stillTracked := indexStates[todos.key()]
+	indexMu.Unlock()
+	if stillTracked {
+		t.Fatal("Close should have evicted the bucket's indexState")
+	}
+}
+
+func TestIndexSharedAcrossHandles(t *testing.T) {
+	db := openTestDB(t)
+	a, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	if err := a.AddIndex("owner", byOwner); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+
+	b, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't reopen bucket: %v", err)
+	}
+	if err := b.Put([]byte("1"), []byte("alice:buy milk")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	values, err := a.QueryIndex("owner", []byte("alice"))
+	if err != nil {
+		t.Fatalf("QueryIndex: %v", err)
+	}
+	if len(values) != 1 {
+		t.Fatalf("QueryIndex(alice) via handle a after write via handle b = %v, want 1 value", values)
+	}
+}
@@ -0,0 +1,328 @@
+// Package rest turns a *buckets.Bucket into a full JSON REST resource.
+//
+// It generalizes the toy post-and-store example in the examples package
+// into something reusable: given a bucket and a factory for the Go type
+// stored in it, Resource exposes the standard CRUD routes over HTTP,
+// handling key generation, (un)marshaling, and status codes so callers
+// don't have to hand-roll a service per bucket.
+package rest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/joyrexus/buckets"
+)
+
+// Factory returns a new zero-value instance of the resource's Go type, e.g.
+//
+//	rest.New(todos, func() interface{} { return new(Todo) }, "/todos")
+type Factory func() interface{}
+
+// Resource adapts a *buckets.Bucket to a JSON REST API. It strips its own
+// mount prefix, so it must be registered under both the bare prefix and
+// its subtree to serve the collection and member routes:
+//
+//	items := rest.New(bucket, func() interface{} { return new(Todo) }, "/items")
+//	http.Handle("/items", items)
+//	http.Handle("/items/", items)
+type Resource struct {
+	bucket *buckets.Bucket
+	new    Factory
+	prefix string
+}
+
+// New returns a Resource backed by bucket, mounted at prefix (e.g.
+// "/items"). new is called to construct a fresh value each time a
+// request body needs to be unmarshaled into one.
+func New(bucket *buckets.Bucket, new Factory, prefix string) *Resource {
+	return &Resource{bucket: bucket, new: new, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+// ServeHTTP dispatches to the CRUD handlers based on method and the
+// resource id trailing the mount point.
+func (rs *Resource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, rs.prefix), "/")
+
+	switch {
+	case id == "count" && r.Method == http.MethodGet:
+		rs.count(w, r)
+	case id == "":
+		switch r.Method {
+		case http.MethodPost:
+			rs.create(w, r)
+		case http.MethodGet:
+			rs.list(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+	default:
+		key := []byte(id)
+		switch r.Method {
+		case http.MethodGet:
+			rs.get(w, r, key)
+		case http.MethodPut:
+			rs.put(w, r, key)
+		case http.MethodPatch:
+			rs.patch(w, r, key)
+		case http.MethodDelete:
+			rs.delete(w, r, key)
+		default:
+			methodNotAllowed(w)
+		}
+	}
+}
+
+// create handles POST /items: decode the body, assign an auto-generated
+// key, store it, and respond 201 with a Location header.
+func (rs *Resource) create(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v := rs.new()
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seq, err := rs.bucket.NextSequence()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := []byte(strconv.FormatUint(seq, 10))
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := rs.bucket.Put(key, encoded); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", r.URL.Path+"/"+string(key))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(encoded)
+}
+
+// list handles GET /items, paginating via ?limit=&after=. Results are
+// ordered by key, matching the bucket's underlying byte-sorted iteration.
+func (rs *Resource) list(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	after := []byte(r.URL.Query().Get("after"))
+
+	var items []json.RawMessage
+	errStop := fmt.Errorf("rest: stop iteration")
+	err := rs.bucket.Map(func(k, v []byte) error {
+		if len(after) > 0 && bytes.Compare(k, after) <= 0 {
+			return nil
+		}
+		items = append(items, append(json.RawMessage{}, v...))
+		if limit > 0 && len(items) >= limit {
+			return errStop
+		}
+		return nil
+	})
+	if err != nil && err != errStop {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// get handles GET /items/{id}.
+func (rs *Resource) get(w http.ResponseWriter, r *http.Request, key []byte) {
+	v, err := rs.bucket.Get(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(v))
+	w.Write(v)
+}
+
+// put handles PUT /items/{id}, replacing the stored value. If the request
+// carries an If-Match header, the write is rejected with 409 Conflict
+// unless it matches the current ETag.
+func (rs *Resource) put(w http.ResponseWriter, r *http.Request, key []byte) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	if match := r.Header.Get("If-Match"); match != "" {
+		current, err := rs.bucket.Get(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if current == nil || etag(current) != match {
+			http.Error(w, "etag mismatch", http.StatusConflict)
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v := rs.new()
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := rs.bucket.Put(key, encoded); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(encoded))
+	w.Write(encoded)
+}
+
+// patch handles PATCH /items/{id}, merging the request body's JSON fields
+// into the stored value.
+func (rs *Resource) patch(w http.ResponseWriter, r *http.Request, key []byte) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	current, err := rs.bucket.Get(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var merged map[string]interface{}
+	if err := json.Unmarshal(current, &merged); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for k, v := range patch {
+		merged[k] = v
+	}
+
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := rs.bucket.Put(key, encoded); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(encoded))
+	w.Write(encoded)
+}
+
+// delete handles DELETE /items/{id}.
+func (rs *Resource) delete(w http.ResponseWriter, r *http.Request, key []byte) {
+	v, err := rs.bucket.Get(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := rs.bucket.Delete(key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// count handles GET /items/count.
+func (rs *Resource) count(w http.ResponseWriter, r *http.Request) {
+	var n int
+	err := rs.bucket.Map(func(k, v []byte) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, "%d", n)
+}
+
+// acceptsJSON rejects requests whose Content-Type isn't JSON with a 415,
+// writing the error response itself. It returns whether the request may
+// proceed.
+func acceptsJSON(w http.ResponseWriter, r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	if ct != "" && !strings.HasPrefix(ct, "application/json") {
+		http.Error(w, "unsupported content type: "+ct, http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
+
+// etag computes a weak content hash of v, suitable for If-Match checks.
+func etag(v []byte) string {
+	sum := sha1.Sum(v)
+	return fmt.Sprintf("%x", sum)
+}
+
+// methodNotAllowed responds 405 for verbs a route doesn't support.
+func methodNotAllowed(w http.ResponseWriter) {
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/joyrexus/buckets"
+)
+
+type todo struct {
+	Task string `json:"task"`
+	Done bool   `json:"done"`
+}
+
+func newTestResource(t *testing.T) *Resource {
+	t.Helper()
+	db, err := buckets.Open(filepath.Join(t.TempDir(), "bolt.db"))
+	if err != nil {
+		t.Fatalf("couldn't open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	bucket, err := db.New([]byte("todos"))
+	if err != nil {
+		t.Fatalf("couldn't create bucket: %v", err)
+	}
+	return New(bucket, func() interface{} { return new(todo) }, "/todos")
+}
+
+func TestCreateReturns201(t *testing.T) {
+	rs := newTestResource(t)
+
+	body := bytes.NewBufferString(`{"task":"buy milk"}`)
+	req := httptest.NewRequest(http.MethodPost, "/todos", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	rs.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /todos status = %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	if loc := w.Header().Get("Location"); loc == "" {
+		t.Fatal("POST /todos response missing Location header")
+	}
+}
+
+func TestGetMissingReturns404(t *testing.T) {
+	rs := newTestResource(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/no-such-id", nil)
+	w := httptest.NewRecorder()
+
+	rs.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("GET /todos/no-such-id status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPutIfMatchConflictReturns409(t *testing.T) {
+	rs := newTestResource(t)
+
+	createReq := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewBufferString(`{"task":"buy milk"}`))
+	createReq.Header.Set("Content-Type", "application/json")
+	createW := httptest.NewRecorder()
+	rs.ServeHTTP(createW, createReq)
+	if createW.Code != http.StatusCreated {
+		t.Fatalf("setup POST /todos status = %d, want %d", createW.Code, http.StatusCreated)
+	}
+	loc := createW.Header().Get("Location")
+
+	putReq := httptest.NewRequest(http.MethodPut, loc, bytes.NewBufferString(`{"task":"buy oat milk"}`))
+	putReq.Header.Set("Content-Type", "application/json")
+	putReq.Header.Set("If-Match", `"stale-etag"`)
+	putW := httptest.NewRecorder()
+	rs.ServeHTTP(putW, putReq)
+
+	if putW.Code != http.StatusConflict {
+		t.Fatalf("PUT with stale If-Match status = %d, want %d", putW.Code, http.StatusConflict)
+	}
+}
+
+func TestUnsupportedContentTypeReturns415(t *testing.T) {
+	rs := newTestResource(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewBufferString(`task=buy milk`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	rs.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("POST with form content-type status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestListRoundTrips(t *testing.T) {
+	rs := newTestResource(t)
+
+	for _, task := range []string{"buy milk", "walk dog"} {
+		body := bytes.NewBufferString(`{"task":"` + task + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/todos", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		rs.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("setup POST /todos status = %d, want %d", w.Code, http.StatusCreated)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /todos status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatalf("couldn't decode list response: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("GET /todos returned %d items, want 2", len(items))
+	}
+}
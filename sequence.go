@@ -0,0 +1,16 @@
+package buckets
+
+import "github.com/boltdb/bolt"
+
+// NextSequence returns a bucket-local, monotonically increasing integer
+// for generating keys, as used by the rest package to assign ids to
+// POSTed resources.
+func (bk *Bucket) NextSequence() (uint64, error) {
+	var seq uint64
+	err := bk.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		seq, err = tx.Bucket(bk.Name).NextSequence()
+		return err
+	})
+	return seq, err
+}
@@ -0,0 +1,59 @@
+// Package serverutil helps turn the post-and-store pattern used
+// throughout the examples package into a production service by providing
+// graceful shutdown for a bucket-backed HTTP server. The health check and
+// Prometheus metrics live on buckets.DB itself (DB.HealthCheck and
+// DB.MetricsHandler).
+package serverutil
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joyrexus/buckets"
+)
+
+// ShutdownTimeout bounds how long RunHTTP waits for in-flight requests to
+// drain before closing db anyway.
+const ShutdownTimeout = 10 * time.Second
+
+// RunHTTP starts srv and blocks until ctx is canceled or the process
+// receives SIGINT/SIGTERM. On either signal it drains in-flight requests
+// via srv.Shutdown and only then closes db, so the database is
+// guaranteed to close after the server has stopped accepting new work
+// rather than racing it via a bare `defer db.Close()` in main.
+func RunHTTP(ctx context.Context, srv *http.Server, db *buckets.DB) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+			return
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		db.Close()
+		return err
+	case <-sig:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	closeErr := db.Close()
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+	return closeErr
+}
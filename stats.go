@@ -0,0 +1,190 @@
+package buckets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the cumulative operation counters tracked across
+// every bucket opened from a DB. Put and Delete report through the same
+// notify hook that drives Watch and the secondary indices; Get reports
+// through the equivalent instrumentation point in Bucket.Get.
+type Stats struct {
+	Puts     uint64
+	Gets     uint64
+	Deletes  uint64
+	BytesIn  uint64
+	BytesOut uint64
+}
+
+// dbStats holds the counters and histogram for one *DB. Kept in a
+// side-table rather than a field on DB so DB can stay a thin embedding of
+// *bolt.DB.
+type dbStats struct {
+	puts, gets, deletes uint64
+	bytesIn, bytesOut   uint64
+	txHist              *histogram
+}
+
+// txBucketBounds are the upper bounds, in seconds, of the transaction
+// duration histogram's buckets, from 1ms to 5s.
+var txBucketBounds = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+var (
+	statsMu sync.Mutex
+	statsBy = map[*DB]*dbStats{}
+)
+
+func statsFor(db *DB) *dbStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	s, ok := statsBy[db]
+	if !ok {
+		s = &dbStats{txHist: newHistogram(txBucketBounds)}
+		statsBy[db] = s
+	}
+	return s
+}
+
+// evictStats drops the counters tracked for db, called from DB.Close.
+func evictStats(db *DB) {
+	statsMu.Lock()
+	delete(statsBy, db)
+	statsMu.Unlock()
+}
+
+// histogram is a minimal Prometheus-style cumulative histogram: each
+// bucket counts every observation less than or equal to its bound.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64
+	sum    float64
+	total  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	b := append([]float64{}, bounds...)
+	sort.Float64s(b)
+	return &histogram{bounds: b, counts: make([]uint64, len(b)+1)}
+}
+
+func (h *histogram) observe(seconds float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += seconds
+	h.total++
+	for i, bound := range h.bounds {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(h.bounds)]++ // +Inf bucket
+}
+
+func (h *histogram) writeTo(w http.ResponseWriter, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.bounds {
+		fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, bound, h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.counts[len(h.bounds)])
+	fmt.Fprintf(w, "%s_sum %g\n", name, h.sum)
+	fmt.Fprintf(w, "%s_count %d\n", name, h.total)
+}
+
+// recordPut, recordDelete, recordGet, and recordTxDuration update the
+// counters backing db's Stats.
+func recordPut(db *DB, n int) {
+	s := statsFor(db)
+	atomic.AddUint64(&s.puts, 1)
+	atomic.AddUint64(&s.bytesIn, uint64(n))
+}
+
+func recordDelete(db *DB) {
+	atomic.AddUint64(&statsFor(db).deletes, 1)
+}
+
+func recordGet(db *DB, n int) {
+	s := statsFor(db)
+	atomic.AddUint64(&s.gets, 1)
+	atomic.AddUint64(&s.bytesOut, uint64(n))
+}
+
+func recordTxDuration(db *DB, d time.Duration) {
+	statsFor(db).txHist.observe(d.Seconds())
+}
+
+// Stats returns a snapshot of the cumulative Put/Get/Delete counters and
+// byte counts recorded across every bucket opened from db. Two
+// independently opened DBs never share counters.
+func (bx *DB) Stats() Stats {
+	s := statsFor(bx)
+	return Stats{
+		Puts:     atomic.LoadUint64(&s.puts),
+		Gets:     atomic.LoadUint64(&s.gets),
+		Deletes:  atomic.LoadUint64(&s.deletes),
+		BytesIn:  atomic.LoadUint64(&s.bytesIn),
+		BytesOut: atomic.LoadUint64(&s.bytesOut),
+	}
+}
+
+// MetricsHandler returns an http.Handler serving db's counters and
+// transaction duration histogram in Prometheus text exposition format,
+// suitable for mounting at /metrics.
+func (bx *DB) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := bx.Stats()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintf(w, "buckets_puts_total %d\n", stats.Puts)
+		fmt.Fprintf(w, "buckets_gets_total %d\n", stats.Gets)
+		fmt.Fprintf(w, "buckets_deletes_total %d\n", stats.Deletes)
+		fmt.Fprintf(w, "buckets_bytes_in_total %d\n", stats.BytesIn)
+		fmt.Fprintf(w, "buckets_bytes_out_total %d\n", stats.BytesOut)
+		statsFor(bx).txHist.writeTo(w, "buckets_tx_duration_seconds")
+	})
+}
+
+// healthCheckKey is the key HealthCheck round-trips in its probe bucket.
+var healthCheckKey = []byte("_healthcheck")
+
+// HealthCheck performs a bounded write-then-read round trip against db,
+// failing if it doesn't complete before ctx is done. It's meant for
+// liveness/readiness probes in long-running services.
+func (bx *DB) HealthCheck(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		b, err := bx.New([]byte("_health"))
+		if err != nil {
+			done <- err
+			return
+		}
+		val := []byte(time.Now().Format(time.RFC3339Nano))
+		if err := b.Put(healthCheckKey, val); err != nil {
+			done <- err
+			return
+		}
+		got, err := b.Get(healthCheckKey)
+		if err != nil {
+			done <- err
+			return
+		}
+		if string(got) != string(val) {
+			done <- fmt.Errorf("buckets: health check read back a stale value")
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
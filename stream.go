@@ -0,0 +1,81 @@
+package buckets
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// record is the on-the-wire shape used by EncodeJSONStream and
+// DecodeJSONStream: one JSON object per line, key/value newline-delimited
+// JSON (ndjson).
+type record struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ImportOptions configures DecodeJSONStream.
+type ImportOptions struct {
+	// BatchSize is the number of records written per transaction. A
+	// large import is split into batches so it doesn't hold a single
+	// write transaction open (and its memory) for the whole stream.
+	// Zero means "commit every record".
+	BatchSize int
+}
+
+// EncodeJSONStream writes every key/value pair in the bucket to w as
+// newline-delimited JSON, one `{"key":..., "value":...}` object per line,
+// inside a single read transaction. It never materializes the whole
+// bucket in memory, so it's safe to use for backing up large buckets.
+func (b *Bucket) EncodeJSONStream(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return b.Map(func(k, v []byte) error {
+		return enc.Encode(record{
+			Key:   string(k),
+			Value: append(json.RawMessage{}, v...),
+		})
+	})
+}
+
+// DecodeJSONStream reads the newline-delimited JSON format written by
+// EncodeJSONStream from r and restores it into the bucket, committing a
+// transaction every opts.BatchSize records so large imports don't build
+// up an unbounded write transaction.
+func (b *Bucket) DecodeJSONStream(r io.Reader, opts ImportOptions) error {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	batch := make([]Item, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		items := make([]struct{ Key, Value []byte }, len(batch))
+		for i, it := range batch {
+			items[i] = struct{ Key, Value []byte }{it.Key, it.Value}
+		}
+		if err := b.Insert(items); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for dec.More() {
+		var rec record
+		if err := dec.Decode(&rec); err != nil {
+			return err
+		}
+		batch = append(batch, Item{Key: []byte(rec.Key), Value: []byte(rec.Value)})
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
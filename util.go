@@ -0,0 +1,8 @@
+package buckets
+
+import "bytes"
+
+// isBefore checks whether `key` is non-nil and sorts at or before `max`.
+func isBefore(key, max []byte) bool {
+	return key != nil && bytes.Compare(key, max) <= 0
+}
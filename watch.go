@@ -0,0 +1,168 @@
+package buckets
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Op identifies the kind of mutation that produced an Event.
+type Op int
+
+// The mutation kinds a Bucket reports to its watchers.
+const (
+	Put Op = iota
+	Delete
+)
+
+func (o Op) String() string {
+	if o == Delete {
+		return "delete"
+	}
+	return "put"
+}
+
+// Event describes a single successful mutation of a bucket. OldValue is
+// nil for a Put that created a new key, and for a Delete of a key that
+// didn't exist.
+type Event struct {
+	Op       Op
+	Key      []byte
+	Value    []byte
+	OldValue []byte
+	TxID     uint64
+}
+
+// watchState holds the watch/notify bookkeeping for one bucket. It's kept
+// out of Bucket itself and looked up by bucketKey (rather than by the
+// bucket's own identity) so that adding change notification doesn't
+// disturb the bucket's existing field layout, and so that two *Bucket
+// handles obtained for the same name share the same state.
+type watchState struct {
+	mu       sync.Mutex
+	nextTxID uint64
+	buffer   []Event
+	watchers []*watcher
+}
+
+// eventBufferSize is the capacity of the ring buffer of recent events
+// kept per bucket so that a reconnecting Changes/SSE client can catch up
+// on a `since` cursor instead of re-reading the whole bucket.
+const eventBufferSize = 1024
+
+var (
+	watchMu     sync.Mutex
+	watchStates = map[bucketKey]*watchState{}
+)
+
+func stateFor(b *Bucket) *watchState {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	k := b.key()
+	s, ok := watchStates[k]
+	if !ok {
+		s = &watchState{}
+		watchStates[k] = s
+	}
+	return s
+}
+
+// evictWatchState drops the watch state tracked for every bucket opened
+// from db. It's called from DB.Close so a long-running process that
+// repeatedly opens and closes databases (tests, rotating snapshots)
+// doesn't leak a watchState per bucket forever.
+func evictWatchState(db *DB) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	for k := range watchStates {
+		if k.db == db {
+			delete(watchStates, k)
+		}
+	}
+}
+
+type watcher struct {
+	prefix []byte
+	ch     chan Event
+}
+
+// Watch returns a channel of events fired after every successful Put or
+// Delete on the bucket. The channel is buffered but not drained
+// automatically: a slow consumer can miss events (see Changes for a
+// cursor-based alternative that tolerates disconnects).
+func (b *Bucket) Watch() <-chan Event {
+	return b.WatchPrefix(nil)
+}
+
+// WatchPrefix is like Watch but only delivers events for keys starting
+// with prefix.
+func (b *Bucket) WatchPrefix(prefix []byte) <-chan Event {
+	s := stateFor(b)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := &watcher{prefix: append([]byte{}, prefix...), ch: make(chan Event, 16)}
+	s.watchers = append(s.watchers, w)
+	return w.ch
+}
+
+// Changes returns the events with TxID > since, for callers implementing
+// a reconnect cursor instead of holding a live channel open. ok is false
+// if since predates the retention window of the ring buffer, meaning
+// events were dropped and the caller must resynchronize out-of-band
+// (e.g. by re-reading the bucket) rather than trust the returned slice.
+//
+// Delivery is at-least-once while the buffer hasn't overflowed: a client
+// that resumes from the TxID of the last event it saw will see every
+// later event exactly once, as long as it reconnects before the buffer
+// wraps.
+func (b *Bucket) Changes(since uint64) (events []Event, ok bool) {
+	s := stateFor(b)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buffer) > 0 && since < s.buffer[0].TxID-1 {
+		return nil, false
+	}
+	for _, ev := range s.buffer {
+		if ev.TxID > since {
+			events = append(events, ev)
+		}
+	}
+	return events, true
+}
+
+// notify is invoked by Put and Delete after a successful mutation. It
+// assigns the event's TxID, appends it to the ring buffer backing
+// Changes, updates any registered indices, and fans the event out to any
+// live watchers, dropping it for watchers whose channel is full rather
+// than blocking the mutation.
+func (b *Bucket) notify(ev Event) {
+	s := stateFor(b)
+	s.mu.Lock()
+	s.nextTxID++
+	ev.TxID = s.nextTxID
+	s.buffer = append(s.buffer, ev)
+	if len(s.buffer) > eventBufferSize {
+		s.buffer = s.buffer[len(s.buffer)-eventBufferSize:]
+	}
+	watchers := append([]*watcher{}, s.watchers...)
+	s.mu.Unlock()
+
+	notifyIndices(b, ev)
+
+	switch ev.Op {
+	case Put:
+		recordPut(b.db, len(ev.Value))
+	case Delete:
+		recordDelete(b.db)
+	}
+
+	for _, w := range watchers {
+		if len(w.prefix) > 0 && !bytes.HasPrefix(ev.Key, w.prefix) {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+		}
+	}
+}